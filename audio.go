@@ -0,0 +1,28 @@
+package chip8
+
+// Audio represents the abstract audio backend for the CHIP-8 virtual
+// machine's sound timer, and, on XO-CHIP, its programmable audio pattern.
+type Audio interface {
+	// Start begins playing the currently loaded pattern. It's called once
+	// when the sound timer becomes nonzero, not on every tick it stays
+	// nonzero.
+	Start() error
+
+	// Stop stops playing. It's called once the sound timer reaches zero.
+	Stop() error
+
+	// LoadPattern loads a 16-byte, 128-sample (1 bit per sample, most
+	// significant bit first) waveform to be played while Start is active,
+	// at the given pitch, as set by the XO-CHIP F002/FX3A opcodes. An
+	// implementation that only supports the classic fixed-frequency beep
+	// may discard this and always play that instead.
+	LoadPattern(pattern [16]byte, pitch byte) error
+}
+
+// silentAudio is the default Audio used when Options.Audio is left nil. It
+// discards LoadPattern and never makes a sound.
+type silentAudio struct{}
+
+func (silentAudio) Start() error                                   { return nil }
+func (silentAudio) Stop() error                                    { return nil }
+func (silentAudio) LoadPattern(pattern [16]byte, pitch byte) error { return nil }
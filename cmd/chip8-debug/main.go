@@ -0,0 +1,159 @@
+// Command chip8-debug runs a ROM under a chip8.Debugger and exposes it over
+// a minimal newline-delimited JSON protocol on a TCP socket, so external
+// UIs can attach without linking against the chip8 package directly.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"os"
+
+	"github.com/wilmerpaulino/chip8"
+)
+
+// nullRenderer discards all rendering. cmd/chip8-debug exposes the display
+// through the debug protocol's "state" command instead of drawing it.
+type nullRenderer struct{}
+
+func (nullRenderer) Render(chip8.Display) error { return nil }
+
+// command is a single request sent by a client, one per line.
+type command struct {
+	Cmd  string `json:"cmd"`
+	Addr uint16 `json:"addr,omitempty"`
+	Key  int    `json:"key,omitempty"`
+}
+
+// response is sent back for every command received.
+type response struct {
+	OK      bool                `json:"ok"`
+	Error   string              `json:"error,omitempty"`
+	State   *chip8.DebugState   `json:"state,omitempty"`
+	Program []chip8.Instruction `json:"program,omitempty"`
+}
+
+// parseMode parses the -mode flag into a chip8.Mode.
+func parseMode(s string) (chip8.Mode, error) {
+	switch s {
+	case "vip":
+		return chip8.ModeVIP, nil
+	case "schip":
+		return chip8.ModeSuperChip, nil
+	case "xochip":
+		return chip8.ModeXOChip, nil
+	default:
+		return 0, fmt.Errorf("unknown mode %q, must be one of: vip, schip, xochip", s)
+	}
+}
+
+// handleConn serves commands for a single client connection until it
+// disconnects or sends malformed JSON.
+func handleConn(conn net.Conn, vm *chip8.VirtualMachine, dbg *chip8.Debugger, rom []byte) {
+	defer conn.Close()
+
+	dec := json.NewDecoder(conn)
+	enc := json.NewEncoder(conn)
+
+	for {
+		var cmd command
+		if err := dec.Decode(&cmd); err != nil {
+			return
+		}
+
+		resp := response{OK: true}
+
+		switch cmd.Cmd {
+		case "step":
+			dbg.Step()
+		case "continue":
+			dbg.Continue()
+		case "pause":
+			dbg.Pause()
+		case "break":
+			dbg.SetBreakpoint(cmd.Addr)
+		case "clear-break":
+			dbg.ClearBreakpoint(cmd.Addr)
+		case "watch":
+			dbg.SetWatchpoint(cmd.Addr)
+		case "clear-watch":
+			dbg.ClearWatchpoint(cmd.Addr)
+		case "key-down":
+			vm.PressKey(cmd.Key)
+		case "key-up":
+			vm.ReleaseKey(cmd.Key)
+		case "state":
+			state := dbg.State()
+			resp.State = &state
+		case "disassemble":
+			resp.Program = chip8.Disassemble(rom)
+		default:
+			resp.OK = false
+			resp.Error = fmt.Sprintf("unknown command %q", cmd.Cmd)
+		}
+
+		if err := enc.Encode(resp); err != nil {
+			return
+		}
+	}
+}
+
+func dbgMain() error {
+	romPath := flag.String("rom", "", "path to ROM file")
+	addr := flag.String("addr", "localhost:8800", "TCP address to listen on")
+	modeFlag := flag.String("mode", "vip", "interpreter mode: vip, schip, or xochip")
+
+	flag.Parse()
+
+	if *romPath == "" {
+		return errors.New("missing -rom")
+	}
+
+	mode, err := parseMode(*modeFlag)
+	if err != nil {
+		return err
+	}
+
+	rom, err := ioutil.ReadFile(*romPath)
+	if err != nil {
+		return fmt.Errorf("failed to read from file: %v", err)
+	}
+
+	vm := chip8.NewWithOptions(nullRenderer{}, chip8.Options{Mode: mode})
+	if err := vm.LoadROM(rom); err != nil {
+		return fmt.Errorf("failed to load rom: %v", err)
+	}
+
+	dbg := chip8.NewDebugger(vm)
+
+	vm.Start()
+	defer vm.Stop()
+
+	ln, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return fmt.Errorf("unable to listen: %v", err)
+	}
+	defer ln.Close()
+
+	log.Printf("chip8-debug listening on %s", ln.Addr())
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return fmt.Errorf("accept failed: %v", err)
+		}
+
+		go handleConn(conn, vm, dbg, rom)
+	}
+}
+
+func main() {
+	if err := dbgMain(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+/*
+#include <stdint.h>
+
+extern void chip8AudioCallback(void *userdata, uint8_t *stream, int length);
+*/
+import "C"
+
+import (
+	"math"
+	"sync"
+	"unsafe"
+
+	"github.com/veandco/go-sdl2/sdl"
+	"github.com/wilmerpaulino/chip8"
+)
+
+const (
+	// audioSampleRate is the sample rate, in Hz, the SDL2 audio device is
+	// opened with.
+	audioSampleRate = 44100
+
+	// audioDefaultFrequency is the frequency of the classic CHIP-8 beep,
+	// played until an XO-CHIP program loads its own pattern via
+	// LoadPattern.
+	audioDefaultFrequency = 440.0
+
+	// audioAmplitude is the signed 16-bit sample value used for the "on"
+	// half of the waveform; its negation is used for the "off" half.
+	audioAmplitude = 3000
+
+	// patternSamples is the number of samples in an XO-CHIP audio
+	// pattern: 16 bytes, one bit per sample.
+	patternSamples = 128
+)
+
+var _ chip8.Audio = (*sdlAudio)(nil)
+
+// sdlAudio is a reference chip8.Audio implementation that streams a
+// synthesized waveform to an SDL2 audio device from a C callback invoked
+// on SDL's dedicated audio thread. It defaults to a 440 Hz square wave,
+// matching the classic CHIP-8 beep, and switches to replaying an
+// arbitrary 128-sample waveform once an XO-CHIP program loads a pattern
+// via LoadPattern.
+type sdlAudio struct {
+	deviceID sdl.AudioDeviceID
+
+	mu         sync.Mutex
+	phase      float64
+	usePattern bool
+	pattern    [patternSamples]bool
+	pitch      byte
+}
+
+// newSdlAudio opens the default SDL2 audio output device, paused, ready
+// for Start to begin playback.
+func newSdlAudio() (*sdlAudio, error) {
+	a := &sdlAudio{pitch: 64}
+
+	spec := &sdl.AudioSpec{
+		Freq:     audioSampleRate,
+		Format:   sdl.AUDIO_S16SYS,
+		Channels: 1,
+		Samples:  2048,
+		Callback: sdl.AudioCallback(C.chip8AudioCallback),
+		UserData: unsafe.Pointer(a),
+	}
+
+	deviceID, err := sdl.OpenAudioDevice("", false, spec, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	a.deviceID = deviceID
+
+	return a, nil
+}
+
+// Close closes the audio device. The sdlAudio must not be used afterward.
+func (a *sdlAudio) Close() {
+	sdl.CloseAudioDevice(a.deviceID)
+}
+
+// Start begins playing the currently loaded waveform.
+func (a *sdlAudio) Start() error {
+	sdl.PauseAudioDevice(a.deviceID, false)
+	return nil
+}
+
+// Stop silences the audio device.
+func (a *sdlAudio) Stop() error {
+	sdl.PauseAudioDevice(a.deviceID, true)
+	return nil
+}
+
+// LoadPattern loads an XO-CHIP audio pattern: 16 bytes, read most
+// significant bit first, giving 128 samples of a single playback cycle,
+// replayed at the rate 4000*2^((pitch-64)/48) Hz, per the XO-CHIP spec.
+func (a *sdlAudio) LoadPattern(pattern [16]byte, pitch byte) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for i := range a.pattern {
+		a.pattern[i] = pattern[i/8]>>(7-uint(i%8))&1 == 1
+	}
+	a.pitch = pitch
+	a.usePattern = true
+
+	return nil
+}
+
+// nextSample advances the waveform by one audio-device sample and returns
+// its signed 16-bit value. Called from chip8AudioCallback, on SDL's audio
+// thread.
+func (a *sdlAudio) nextSample() int16 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.usePattern {
+		cycleLen := audioSampleRate / audioDefaultFrequency
+		cyclePos := math.Mod(a.phase, cycleLen)
+		a.phase++
+
+		if cyclePos < cycleLen/2 {
+			return audioAmplitude
+		}
+		return -audioAmplitude
+	}
+
+	rate := 4000 * math.Pow(2, (float64(a.pitch)-64)/48)
+	step := rate * patternSamples / audioSampleRate
+
+	idx := int(a.phase) % patternSamples
+	a.phase += step
+	for a.phase >= patternSamples {
+		a.phase -= patternSamples
+	}
+
+	if a.pattern[idx] {
+		return audioAmplitude
+	}
+	return -audioAmplitude
+}
+
+//export chip8AudioCallback
+func chip8AudioCallback(userdata unsafe.Pointer, stream *C.uint8_t, length C.int) {
+	a := (*sdlAudio)(userdata)
+
+	samples := int(length) / 2
+	buf := unsafe.Slice((*int16)(unsafe.Pointer(stream)), samples)
+
+	for i := 0; i < samples; i++ {
+		buf[i] = a.nextSample()
+	}
+}
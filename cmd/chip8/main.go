@@ -16,7 +16,7 @@ const (
 )
 
 var (
-	keyMap = map[sdl.Keycode]int{
+	keyMap = map[sdl.Keycode]uint8{
 		sdl.K_1: 0x1,
 		sdl.K_2: 0x2,
 		sdl.K_3: 0x3,
@@ -40,6 +40,7 @@ var (
 
 type sdlRenderer struct {
 	*sdl.Renderer
+	window *sdl.Window
 }
 
 func createSdlRenderer(window *sdl.Window, flags uint32) (*sdlRenderer, error) {
@@ -48,20 +49,31 @@ func createSdlRenderer(window *sdl.Window, flags uint32) (*sdlRenderer, error) {
 		return nil, err
 	}
 
-	return &sdlRenderer{r}, nil
+	return &sdlRenderer{Renderer: r, window: window}, nil
 }
 
 func (r *sdlRenderer) Render(display chip8.Display) error {
-	for x := 0; x < chip8.DisplayWidth; x++ {
-		for y := 0; y < chip8.DisplayHeight; y++ {
+	width, height := display.Width(), display.Height()
+
+	// Hi-res (SCHIP/XO-CHIP) modes pack twice as many pixels per axis, so
+	// halve the pixel size to keep the window a similar physical size.
+	scale := int32(pixelSize)
+	if width > chip8.DisplayWidth || height > chip8.DisplayHeight {
+		scale = pixelSize / 2
+	}
+
+	r.window.SetSize(int32(width)*scale, int32(height)*scale)
+
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
 			rect := &sdl.Rect{
-				X: int32(x * pixelSize),
-				Y: int32(y * pixelSize),
-				W: pixelSize,
-				H: pixelSize,
+				X: int32(x) * scale,
+				Y: int32(y) * scale,
+				W: scale,
+				H: scale,
 			}
 
-			color := display[y][x] * 0xff
+			color := display.At(x, y) * 0xff
 			r.SetDrawColor(color, color, color, color)
 
 			if err := r.FillRect(rect); err != nil {
@@ -75,13 +87,26 @@ func (r *sdlRenderer) Render(display chip8.Display) error {
 	return nil
 }
 
-func (r *sdlRenderer) Beep() error {
-	// TODO: Implement beep.
-	return nil
+// parseMode parses the -mode flag into a chip8.Mode.
+func parseMode(s string) (chip8.Mode, error) {
+	switch s {
+	case "vip":
+		return chip8.ModeVIP, nil
+	case "schip":
+		return chip8.ModeSuperChip, nil
+	case "xochip":
+		return chip8.ModeXOChip, nil
+	default:
+		return 0, fmt.Errorf("unknown mode %q, must be one of: vip, schip, xochip", s)
+	}
 }
 
 func vmMain() error {
 	romPath := flag.String("rom", "", "path to ROM file")
+	modeFlag := flag.String("mode", "vip", "interpreter mode: vip, schip, or xochip")
+	rplFlagsPath := flag.String("rpl-flags", "", "path used to persist SCHIP/XO-CHIP RPL flags (FX75/FX85)")
+	clockSpeed := flag.Int("ips", 0, "CPU clock speed, in instructions per second (0 uses the library default)")
+	randSeed := flag.Int64("seed", 0, "seed for the RNG used by the 0xC000 opcode (0 uses a time-based seed)")
 
 	flag.Parse()
 
@@ -115,16 +140,40 @@ func vmMain() error {
 	}
 	defer renderer.Destroy()
 
+	audio, err := newSdlAudio()
+	if err != nil {
+		return fmt.Errorf("unable to open audio device: %v", err)
+	}
+	defer audio.Close()
+
 	if *romPath == "" {
 		flag.Usage()
 	}
 
+	mode, err := parseMode(*modeFlag)
+	if err != nil {
+		return err
+	}
+
 	rom, err := ioutil.ReadFile(*romPath)
 	if err != nil {
 		return fmt.Errorf("failed to read from file: %v", err)
 	}
 
-	vm := chip8.New(renderer)
+	// The default in-memory InputSource is driven directly from SDL's
+	// event loop below, feeding FX0A and EX9E/EXA1 the keys it pumps out
+	// of sdl.PollEvent.
+	source := chip8.NewInputSource()
+	input := source.(chip8.KeyInput)
+
+	vm := chip8.NewWithOptions(renderer, chip8.Options{
+		Mode:         mode,
+		RPLFlagsPath: *rplFlagsPath,
+		InputSource:  source,
+		ClockSpeed:   *clockSpeed,
+		RandSeed:     *randSeed,
+		Audio:        audio,
+	})
 	if err := vm.LoadROM(rom); err != nil {
 		return fmt.Errorf("failed to load rom: %v", err)
 	}
@@ -144,12 +193,16 @@ out:
 			case *sdl.QuitEvent:
 				break out
 			case *sdl.KeyboardEvent:
-				keycode := e.Keysym.Sym
+				key, ok := keyMap[e.Keysym.Sym]
+				if !ok {
+					continue
+				}
+
 				switch e.Type {
 				case sdl.KEYDOWN:
-					vm.PressKey(keyMap[keycode])
+					input.PressKey(key)
 				case sdl.KEYUP:
-					vm.ReleaseKey(keyMap[keycode])
+					input.ReleaseKey(key)
 				}
 			}
 		}
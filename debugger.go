@@ -0,0 +1,246 @@
+package chip8
+
+import "sync"
+
+// runState represents the run-mode of a VirtualMachine's CPU clock, as
+// controlled by its Debugger.
+type runState int
+
+const (
+	runStateRunning runState = iota
+	runStatePaused
+	runStateStepping
+)
+
+// Event is published on Debugger.Events after every instruction executed
+// while a Debugger is attached.
+type Event struct {
+	// PC is the program counter after the instruction executed.
+	PC uint16 `json:"pc"`
+
+	// I is the address register after the instruction executed.
+	I uint16 `json:"i"`
+
+	// V holds the general-purpose registers after the instruction
+	// executed.
+	V [numRegisters]byte `json:"v"`
+
+	// Breakpoint is true if this event paused the virtual machine
+	// because PC reached a configured breakpoint.
+	Breakpoint bool `json:"breakpoint"`
+
+	// Watchpoint is true if this event paused the virtual machine
+	// because a configured watchpoint address changed. WatchpointAddr
+	// holds the address in that case.
+	Watchpoint     bool   `json:"watchpoint"`
+	WatchpointAddr uint16 `json:"watchpointAddr,omitempty"`
+}
+
+// DebugState is a read-only snapshot of a VirtualMachine's state, as
+// returned by Debugger.State.
+type DebugState struct {
+	PC      uint16             `json:"pc"`
+	I       uint16             `json:"i"`
+	SP      byte               `json:"sp"`
+	V       [numRegisters]byte `json:"v"`
+	Stack   [numFrames]uint16  `json:"stack"`
+	Memory  [memorySize]byte   `json:"memory"`
+	Display Display            `json:"display"`
+}
+
+// Debugger attaches to a VirtualMachine to provide breakpoints, single
+// stepping, and read-only introspection of its registers, memory, stack,
+// and display. It is the basis for external tooling such as the
+// cmd/chip8-debug TCP/JSON server.
+//
+// A VirtualMachine with a Debugger attached starts paused; call Continue
+// to let it run normally.
+type Debugger struct {
+	vm *VirtualMachine
+
+	mu          sync.Mutex
+	state       runState
+	resume      chan struct{}
+	breakpoints map[uint16]bool
+	watchpoints map[uint16]bool
+	watchValues map[uint16]byte
+
+	events chan Event
+}
+
+// NewDebugger creates a Debugger attached to vm. vm must not have been
+// started yet.
+func NewDebugger(vm *VirtualMachine) *Debugger {
+	d := &Debugger{
+		vm:          vm,
+		state:       runStatePaused,
+		resume:      make(chan struct{}),
+		breakpoints: make(map[uint16]bool),
+		watchpoints: make(map[uint16]bool),
+		watchValues: make(map[uint16]byte),
+		events:      make(chan Event, 64),
+	}
+
+	vm.debugger = d
+
+	return d
+}
+
+// Continue resumes normal execution.
+func (d *Debugger) Continue() {
+	d.mu.Lock()
+	d.state = runStateRunning
+	d.wake()
+	d.mu.Unlock()
+}
+
+// Pause halts execution before the next instruction is run.
+func (d *Debugger) Pause() {
+	d.mu.Lock()
+	d.state = runStatePaused
+	d.mu.Unlock()
+}
+
+// Step executes a single instruction, then pauses again.
+func (d *Debugger) Step() {
+	d.mu.Lock()
+	d.state = runStateStepping
+	d.wake()
+	d.mu.Unlock()
+}
+
+// SetBreakpoint pauses execution whenever the program counter reaches addr.
+func (d *Debugger) SetBreakpoint(addr uint16) {
+	d.mu.Lock()
+	d.breakpoints[addr] = true
+	d.mu.Unlock()
+}
+
+// ClearBreakpoint removes a previously set breakpoint.
+func (d *Debugger) ClearBreakpoint(addr uint16) {
+	d.mu.Lock()
+	delete(d.breakpoints, addr)
+	d.mu.Unlock()
+}
+
+// SetWatchpoint pauses execution whenever the byte at addr changes.
+//
+// NOTE: this is checked after each instruction completes by comparing the
+// byte's value, so it catches writes that change it, but not reads, nor
+// writes that leave the value unchanged.
+func (d *Debugger) SetWatchpoint(addr uint16) {
+	d.mu.Lock()
+	d.watchpoints[addr] = true
+	d.watchValues[addr] = d.vm.memory[addr]
+	d.mu.Unlock()
+}
+
+// ClearWatchpoint removes a previously set watchpoint.
+func (d *Debugger) ClearWatchpoint(addr uint16) {
+	d.mu.Lock()
+	delete(d.watchpoints, addr)
+	delete(d.watchValues, addr)
+	d.mu.Unlock()
+}
+
+// Events returns the channel Events are published on. Events are dropped,
+// rather than blocking the virtual machine, if the channel isn't drained
+// quickly enough.
+func (d *Debugger) Events() <-chan Event {
+	return d.events
+}
+
+// State returns a read-only snapshot of the virtual machine's current
+// state.
+//
+// State locks against run via vm.stateMu, which run holds while stepping
+// the virtual machine, so the snapshot never observes a step
+// half-applied. run releases stateMu while a step is blocked waiting for
+// input (FX0A), so State still returns promptly for a ROM sitting at an
+// unanswered key prompt instead of hanging until a key is pressed.
+func (d *Debugger) State() DebugState {
+	vm := d.vm
+
+	vm.stateMu.Lock()
+	defer vm.stateMu.Unlock()
+
+	display := newDisplay(vm.display.width, vm.display.height)
+	for y := range display.pixels {
+		copy(display.pixels[y], vm.display.pixels[y])
+	}
+
+	return DebugState{
+		PC:      vm.pc,
+		I:       vm.i,
+		SP:      vm.sp,
+		V:       vm.v,
+		Stack:   vm.stack,
+		Memory:  vm.memory,
+		Display: display,
+	}
+}
+
+// wake unblocks any goroutine currently in awaitTurn. The caller must hold
+// d.mu.
+func (d *Debugger) wake() {
+	close(d.resume)
+	d.resume = make(chan struct{})
+}
+
+// awaitTurn blocks the CPU goroutine while the debugger is paused. It
+// returns false if quit fires first, signaling that run should stop.
+func (d *Debugger) awaitTurn(quit <-chan struct{}) bool {
+	for {
+		d.mu.Lock()
+		state := d.state
+		resume := d.resume
+		d.mu.Unlock()
+
+		if state != runStatePaused {
+			return true
+		}
+
+		select {
+		case <-resume:
+		case <-quit:
+			return false
+		}
+	}
+}
+
+// afterStep is called by run once per executed instruction. It publishes
+// an Event describing the virtual machine's new state, pausing execution
+// if a breakpoint or watchpoint was hit.
+func (d *Debugger) afterStep() {
+	vm := d.vm
+	ev := Event{PC: vm.pc, I: vm.i, V: vm.v}
+
+	d.mu.Lock()
+	if d.state == runStateStepping {
+		d.state = runStatePaused
+	}
+
+	if d.breakpoints[vm.pc] {
+		ev.Breakpoint = true
+	}
+
+	for addr := range d.watchpoints {
+		val := vm.memory[addr]
+		if d.watchValues[addr] != val {
+			d.watchValues[addr] = val
+			ev.Watchpoint = true
+			ev.WatchpointAddr = addr
+			break
+		}
+	}
+	d.mu.Unlock()
+
+	if ev.Breakpoint || ev.Watchpoint {
+		d.Pause()
+	}
+
+	select {
+	case d.events <- ev:
+	default:
+	}
+}
@@ -0,0 +1,169 @@
+package chip8
+
+import "fmt"
+
+// Instruction is a single disassembled CHIP-8 instruction, as produced by
+// Disassemble.
+type Instruction struct {
+	// Address is the memory address the instruction would be loaded at.
+	Address uint16 `json:"address"`
+
+	// Opcode is the raw, two-byte opcode.
+	Opcode opcode `json:"opcode"`
+
+	// Text is the instruction's human-readable mnemonic, e.g. "DRW V0, V1, 5".
+	Text string `json:"text"`
+}
+
+// String returns the instruction's opcode followed by its mnemonic, e.g.
+// "0xD015 DRW V0, V1, 5".
+func (i Instruction) String() string {
+	return fmt.Sprintf("%v %s", i.Opcode, i.Text)
+}
+
+// Disassemble decodes every instruction in rom, as if loaded at
+// memoryOffset, into a human-readable mnemonic.
+//
+// NOTE: CHIP-8 has no way to distinguish code from data ahead of time, so
+// any sprite bytes embedded in rom will disassemble as, likely nonsensical,
+// instructions.
+func Disassemble(rom []byte) []Instruction {
+	instructions := make([]Instruction, 0, len(rom)/2)
+
+	for i := 0; i+1 < len(rom); i += 2 {
+		op := opcode(uint16(rom[i])<<8 | uint16(rom[i+1]))
+
+		instructions = append(instructions, Instruction{
+			Address: memoryOffset + uint16(i),
+			Opcode:  op,
+			Text:    disassembleOpcode(op),
+		})
+	}
+
+	return instructions
+}
+
+// disassembleOpcode returns the mnemonic for a single opcode, in the style
+// of Cowgod's CHIP-8 technical reference.
+func disassembleOpcode(op opcode) string {
+	x := op.RegisterIndex(true)
+	y := op.RegisterIndex(false)
+	nnn := op.Address()
+	nn := op.ByteConstant()
+	n := op.NibbleConstant()
+
+	switch op & 0xf000 {
+	case 0x0000:
+		switch {
+		case op == 0x00e0:
+			return "CLS"
+		case op == 0x00ee:
+			return "RET"
+		case op&0xfff0 == 0x00c0:
+			return fmt.Sprintf("SCD %d", n)
+		case op == 0x00fb:
+			return "SCR"
+		case op == 0x00fc:
+			return "SCL"
+		case op == 0x00fd:
+			return "EXIT"
+		case op == 0x00fe:
+			return "LOW"
+		case op == 0x00ff:
+			return "HIGH"
+		default:
+			return fmt.Sprintf("SYS 0x%03X", nnn)
+		}
+	case 0x1000:
+		return fmt.Sprintf("JP 0x%03X", nnn)
+	case 0x2000:
+		return fmt.Sprintf("CALL 0x%03X", nnn)
+	case 0x3000:
+		return fmt.Sprintf("SE V%X, 0x%02X", x, nn)
+	case 0x4000:
+		return fmt.Sprintf("SNE V%X, 0x%02X", x, nn)
+	case 0x5000:
+		return fmt.Sprintf("SE V%X, V%X", x, y)
+	case 0x6000:
+		return fmt.Sprintf("LD V%X, 0x%02X", x, nn)
+	case 0x7000:
+		return fmt.Sprintf("ADD V%X, 0x%02X", x, nn)
+	case 0x8000:
+		switch op & 0x000f {
+		case 0x0000:
+			return fmt.Sprintf("LD V%X, V%X", x, y)
+		case 0x0001:
+			return fmt.Sprintf("OR V%X, V%X", x, y)
+		case 0x0002:
+			return fmt.Sprintf("AND V%X, V%X", x, y)
+		case 0x0003:
+			return fmt.Sprintf("XOR V%X, V%X", x, y)
+		case 0x0004:
+			return fmt.Sprintf("ADD V%X, V%X", x, y)
+		case 0x0005:
+			return fmt.Sprintf("SUB V%X, V%X", x, y)
+		case 0x0006:
+			return fmt.Sprintf("SHR V%X, V%X", x, y)
+		case 0x0007:
+			return fmt.Sprintf("SUBN V%X, V%X", x, y)
+		case 0x000e:
+			return fmt.Sprintf("SHL V%X, V%X", x, y)
+		default:
+			return fmt.Sprintf("0x%04X", uint16(op))
+		}
+	case 0x9000:
+		return fmt.Sprintf("SNE V%X, V%X", x, y)
+	case 0xa000:
+		return fmt.Sprintf("LD I, 0x%03X", nnn)
+	case 0xb000:
+		return fmt.Sprintf("JP V0, 0x%03X", nnn)
+	case 0xc000:
+		return fmt.Sprintf("RND V%X, 0x%02X", x, nn)
+	case 0xd000:
+		return fmt.Sprintf("DRW V%X, V%X, %d", x, y, n)
+	case 0xe000:
+		switch op & 0x00ff {
+		case 0x009e:
+			return fmt.Sprintf("SKP V%X", x)
+		case 0x00a1:
+			return fmt.Sprintf("SKNP V%X", x)
+		default:
+			return fmt.Sprintf("0x%04X", uint16(op))
+		}
+	case 0xf000:
+		switch op & 0x00ff {
+		case 0x0002:
+			return "LD AUD, [I]"
+		case 0x0007:
+			return fmt.Sprintf("LD V%X, DT", x)
+		case 0x000a:
+			return fmt.Sprintf("LD V%X, K", x)
+		case 0x0015:
+			return fmt.Sprintf("LD DT, V%X", x)
+		case 0x0018:
+			return fmt.Sprintf("LD ST, V%X", x)
+		case 0x001e:
+			return fmt.Sprintf("ADD I, V%X", x)
+		case 0x0029:
+			return fmt.Sprintf("LD F, V%X", x)
+		case 0x0030:
+			return fmt.Sprintf("LD HF, V%X", x)
+		case 0x003a:
+			return fmt.Sprintf("PITCH V%X", x)
+		case 0x0033:
+			return fmt.Sprintf("LD B, V%X", x)
+		case 0x0055:
+			return fmt.Sprintf("LD [I], V%X", x)
+		case 0x0065:
+			return fmt.Sprintf("LD V%X, [I]", x)
+		case 0x0075:
+			return fmt.Sprintf("LD R, V%X", x)
+		case 0x0085:
+			return fmt.Sprintf("LD V%X, R", x)
+		default:
+			return fmt.Sprintf("0x%04X", uint16(op))
+		}
+	default:
+		return fmt.Sprintf("0x%04X", uint16(op))
+	}
+}
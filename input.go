@@ -0,0 +1,118 @@
+package chip8
+
+import (
+	"context"
+	"sync"
+)
+
+// InputSource represents the abstract keyboard input of the CHIP-8 virtual
+// machine. It is queried by the EX9E/EXA1 "skip if (not) pressed" opcodes
+// and blocked on by FX0A ("wait for key press").
+type InputSource interface {
+	// IsPressed reports whether the given key (0x0-0xF) is currently
+	// pressed.
+	IsPressed(key uint8) bool
+
+	// WaitKey blocks until a key transitions from released to pressed,
+	// returning its index. A key merely being held does not satisfy it;
+	// only a press does. The default implementation also counts a press
+	// that happened before the call and was not yet consumed by an
+	// earlier WaitKey. It returns ctx.Err() if ctx is done first.
+	WaitKey(ctx context.Context) (uint8, error)
+}
+
+// KeyInput is implemented by InputSources whose state is driven by
+// explicit key press/release calls, such as the default in-memory
+// implementation returned by NewInputSource. VirtualMachine.PressKey and
+// ReleaseKey use it when the configured InputSource implements it.
+type KeyInput interface {
+	PressKey(key uint8)
+	ReleaseKey(key uint8)
+}
+
+// memoryInput is the default, in-memory InputSource. Its state is driven
+// entirely by calls to PressKey/ReleaseKey, typically forwarded from a
+// Renderer-driven event loop, such as the SDL one in cmd/chip8.
+type memoryInput struct {
+	mu      sync.Mutex
+	pressed [numKeys]bool
+
+	// pending marks keys that have transitioned from released to pressed
+	// since the last time WaitKey consumed them, so a press recorded
+	// before WaitKey starts waiting isn't lost. See setPressed and
+	// WaitKey.
+	pending [numKeys]bool
+	signal  chan struct{}
+}
+
+// NewInputSource creates the default, in-memory InputSource.
+func NewInputSource() InputSource {
+	return &memoryInput{signal: make(chan struct{})}
+}
+
+// IsPressed reports whether the given key is currently pressed.
+func (s *memoryInput) IsPressed(key uint8) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if int(key) >= numKeys {
+		return false
+	}
+
+	return s.pressed[key]
+}
+
+// PressKey signals that the given key was pressed.
+func (s *memoryInput) PressKey(key uint8) {
+	s.setPressed(key, true)
+}
+
+// ReleaseKey signals that the given key was released.
+func (s *memoryInput) ReleaseKey(key uint8) {
+	s.setPressed(key, false)
+}
+
+// setPressed updates the key's state, waking up any pending WaitKey call
+// if it changed. A transition to pressed marks the key pending so a
+// WaitKey call that hasn't started waiting yet doesn't miss it.
+func (s *memoryInput) setPressed(key uint8, pressed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if int(key) >= numKeys || s.pressed[key] == pressed {
+		return
+	}
+
+	s.pressed[key] = pressed
+	if pressed {
+		s.pending[key] = true
+	}
+	close(s.signal)
+	s.signal = make(chan struct{})
+}
+
+// WaitKey blocks until a key transitions from released to pressed. It
+// checks for an already-pending transition before waiting on signal, so a
+// press that happened just before WaitKey was called — and already closed
+// signal — is still observed instead of being missed.
+func (s *memoryInput) WaitKey(ctx context.Context) (uint8, error) {
+	for {
+		s.mu.Lock()
+		for i := 0; i < numKeys; i++ {
+			if s.pending[i] {
+				s.pending[i] = false
+				key := uint8(i)
+				s.mu.Unlock()
+				return key, nil
+			}
+		}
+		signal := s.signal
+		s.mu.Unlock()
+
+		select {
+		case <-signal:
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package chip8
+
+import (
+	"fmt"
+	"sync"
+)
+
+// TraceEntry records that, at Cycle, the virtual machine's key state
+// became Keys. It's the unit recorded by Recorder and consumed by Replay.
+type TraceEntry struct {
+	Cycle uint64
+	Keys  [numKeys]bool
+}
+
+// Recorder captures a compact input trace for a VirtualMachine, suitable
+// for deterministic reproduction via Replay. Rather than logging every
+// cycle, it only records the key state when it changes, keeping traces
+// small for long sessions.
+//
+// A Recorder must be created before the virtual machine is started.
+type Recorder struct {
+	vm *VirtualMachine
+
+	mu       sync.Mutex
+	cycle    uint64
+	lastKeys [numKeys]bool
+	trace    []TraceEntry
+}
+
+// NewRecorder creates a Recorder attached to vm. vm must not have been
+// started yet.
+func NewRecorder(vm *VirtualMachine) *Recorder {
+	r := &Recorder{vm: vm}
+	vm.recorder = r
+
+	return r
+}
+
+// Trace returns the input trace recorded so far, as compact (cycle,
+// keyState) diffs suitable for Replay.
+func (r *Recorder) Trace() []TraceEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	trace := make([]TraceEntry, len(r.trace))
+	copy(trace, r.trace)
+
+	return trace
+}
+
+// sample records the current key state if it changed since the previous
+// cycle. It's called by run once per executed instruction.
+func (r *Recorder) sample() {
+	var keys [numKeys]bool
+	for i := 0; i < numKeys; i++ {
+		keys[i] = r.vm.input.IsPressed(uint8(i))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cycle++
+	if keys != r.lastKeys {
+		r.lastKeys = keys
+		r.trace = append(r.trace, TraceEntry{Cycle: r.cycle, Keys: keys})
+	}
+}
+
+// Replay restores snapshot into a new VirtualMachine, then drives it one
+// instruction at a time for cycles instructions, applying trace's key
+// states at their recorded cycles and ticking the delay/sound timers
+// deterministically according to the virtual machine's configured clock
+// speed, instead of the wall-clock cpuClock/timerClock goroutines Start
+// would use. The same snapshot, trace and options always replay to the
+// same state, which makes it useful for regression tests against test ROMs
+// and for reproducing bug reports from a Snapshot/trace pair. Timer ticks
+// are not part of the trace: a session recorded under Start, whose timers
+// ran on the wall clock, is reproduced exactly only where its timer ticks
+// fell once every ClockSpeed/60 instructions.
+//
+// Key states are applied before step is called for the cycle they're
+// recorded at, so a trace entry that presses a key the same cycle as an
+// FX0A wait relies on memoryInput.WaitKey checking for an already-pending
+// transition rather than missing it while waiting on a signal that fired
+// before the wait began.
+//
+// Replay stops early if the program executes the 00FD ("exit interpreter")
+// opcode. The returned VirtualMachine has not been started, and its
+// input/renderer come from opts, mirroring NewWithOptions.
+func Replay(snapshot []byte, trace []TraceEntry, r Renderer, opts Options, cycles uint64) (*VirtualMachine, error) {
+	vm := NewWithOptions(r, opts)
+	if err := vm.Restore(snapshot); err != nil {
+		return nil, fmt.Errorf("chip8: replay failed to restore snapshot: %v", err)
+	}
+
+	ki, _ := vm.input.(KeyInput)
+
+	ips := vm.ips
+	if ips <= 0 {
+		ips = int(defaultClockSpeed)
+	}
+
+	cyclesPerTick := ips / int(timerSpeed)
+	if cyclesPerTick < 1 {
+		cyclesPerTick = 1
+	}
+
+	idx := 0
+	for cycle := uint64(1); cycle <= cycles; cycle++ {
+		for idx < len(trace) && trace[idx].Cycle == cycle {
+			if ki != nil {
+				for key, pressed := range trace[idx].Keys {
+					if pressed {
+						ki.PressKey(uint8(key))
+					} else {
+						ki.ReleaseKey(uint8(key))
+					}
+				}
+			}
+			idx++
+		}
+
+		vm.stateMu.Lock()
+		err := vm.step()
+		vm.stateMu.Unlock()
+		if err != nil {
+			if err == errExit {
+				break
+			}
+
+			return vm, fmt.Errorf("chip8: replay failed at cycle %d: %v", cycle, err)
+		}
+
+		if cycle%uint64(cyclesPerTick) == 0 {
+			vm.tickTimers()
+		}
+	}
+
+	return vm, nil
+}
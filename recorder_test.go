@@ -0,0 +1,76 @@
+package chip8_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wilmerpaulino/chip8"
+	"github.com/wilmerpaulino/chip8/renderer/headless"
+)
+
+// TestSnapshotRestoreRoundTrip checks that Restore(Snapshot()) reproduces a
+// virtual machine's visible state.
+func TestSnapshotRestoreRoundTrip(t *testing.T) {
+	vm := chip8.NewWithOptions(headless.New(), chip8.Options{})
+	if err := vm.LoadROM([]byte{0x60, 0x2a}); err != nil { // LD V0, 0x2A
+		t.Fatalf("LoadROM failed: %v", err)
+	}
+
+	data, err := vm.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+
+	restored := chip8.NewWithOptions(headless.New(), chip8.Options{})
+	if err := restored.Restore(data); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+
+	dbg := chip8.NewDebugger(restored)
+	state := dbg.State()
+	if state.PC != 0x0200 {
+		t.Errorf("PC = 0x%04X, want 0x0200", state.PC)
+	}
+}
+
+// TestReplayFX0A regresses a deadlock where Replay applied a trace's key
+// press before stepping the cycle that consumed it, and WaitKey required a
+// transition witnessed strictly after it started waiting — so a press
+// recorded for the very cycle FX0A ran on was never observed.
+func TestReplayFX0A(t *testing.T) {
+	vm := chip8.NewWithOptions(headless.New(), chip8.Options{})
+	if err := vm.LoadROM([]byte{0xf0, 0x0a}); err != nil { // LD V0, K
+		t.Fatalf("LoadROM failed: %v", err)
+	}
+
+	snapshot, err := vm.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+
+	trace := []chip8.TraceEntry{{Cycle: 1}}
+	trace[0].Keys[5] = true
+
+	done := make(chan struct{})
+	var replayed *chip8.VirtualMachine
+	var replayErr error
+	go func() {
+		defer close(done)
+		replayed, replayErr = chip8.Replay(snapshot, trace, headless.New(), chip8.Options{}, 1)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Replay did not return; FX0A likely deadlocked on WaitKey")
+	}
+
+	if replayErr != nil {
+		t.Fatalf("Replay failed: %v", replayErr)
+	}
+
+	dbg := chip8.NewDebugger(replayed)
+	if state := dbg.State(); state.V[0] != 5 {
+		t.Errorf("V0 = %d, want 5", state.V[0])
+	}
+}
@@ -1,28 +1,91 @@
 package chip8
 
+import "encoding/json"
+
 const (
-	// DisplayWidth is the width of the display in pixels.
+	// DisplayWidth is the width of the low-resolution display, used by the
+	// original CHIP-8 interpreter, in pixels.
 	DisplayWidth = 64
 
-	// DisplayHeight is the height of the display in pixels.
+	// DisplayHeight is the height of the low-resolution display, used by
+	// the original CHIP-8 interpreter, in pixels.
 	DisplayHeight = 32
+
+	// HiResDisplayWidth is the width of the high-resolution display, used
+	// by SCHIP and XO-CHIP once switched into hi-res mode, in pixels.
+	HiResDisplayWidth = 128
+
+	// HiResDisplayHeight is the height of the high-resolution display,
+	// used by SCHIP and XO-CHIP once switched into hi-res mode, in pixels.
+	HiResDisplayHeight = 64
 )
 
 // Renderer represents the abstract renderer for the CHIP-8 virtual machine.
 type Renderer interface {
 	// Render renders the display.
 	Render(display Display) error
+}
+
+// Display represents the display of the CHIP-8 virtual machine. Unlike the
+// original interpreter, which only ever had a fixed 64x32 resolution, its
+// dimensions can change at runtime: SCHIP and XO-CHIP toggle between the
+// low-resolution and a 128x64 high-resolution mode via the 00FE/00FF
+// opcodes. Renderer implementations should read Width/Height on every
+// frame rather than assuming DisplayWidth/DisplayHeight.
+type Display struct {
+	width  int
+	height int
+	pixels [][]byte
+}
+
+// newDisplay creates a new, cleared display with the given dimensions.
+func newDisplay(width, height int) Display {
+	pixels := make([][]byte, height)
+	for y := range pixels {
+		pixels[y] = make([]byte, width)
+	}
 
-	// Beep makes an audible beep.
-	Beep() error
+	return Display{width: width, height: height, pixels: pixels}
 }
 
-// Display represents the display of the CHIP-8 virtual machine.
-type Display [DisplayHeight][DisplayWidth]byte
+// Width returns the width of the display, in pixels.
+func (d Display) Width() int {
+	return d.width
+}
+
+// Height returns the height of the display, in pixels.
+func (d Display) Height() int {
+	return d.height
+}
+
+// At returns the state of the pixel at the given coordinates. A non-zero
+// value means the pixel is set.
+func (d Display) At(x, y int) byte {
+	return d.pixels[y][x]
+}
+
+// MarshalJSON encodes the display as its width, height, and pixel rows.
+// Display's fields are otherwise unexported, so the default encoding would
+// produce an empty object.
+func (d Display) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Width  int      `json:"width"`
+		Height int      `json:"height"`
+		Pixels [][]byte `json:"pixels"`
+	}{
+		Width:  d.width,
+		Height: d.height,
+		Pixels: d.pixels,
+	})
+}
 
-// drawSprite draws the sprite on the display. The bool returned signifies that
-// a pixel was flipped from set to unset while the sprite was drawn.
-func (d *Display) drawSprite(sprite []byte, x, y uint8) bool {
+// drawSprite draws the sprite on the display. The bool returned signifies
+// that a pixel was flipped from set to unset while the sprite was drawn.
+//
+// If clip is true, pixels that would fall past the display's edges are
+// discarded instead of wrapping around to the opposite edge, matching
+// SCHIP/XO-CHIP's quirk; see VirtualMachine.clips.
+func (d *Display) drawSprite(sprite []byte, x, y uint8, clip bool) bool {
 	flipped := false
 
 	// First, we'll go through every byte of the sprite. Each byte
@@ -32,23 +95,81 @@ func (d *Display) drawSprite(sprite []byte, x, y uint8) bool {
 		// Get the row of pixels.
 		pixels := sprite[i]
 
+		yPos := int(y)%d.height + int(i)
+		if clip {
+			if yPos >= d.height {
+				continue
+			}
+		} else {
+			yPos %= d.height
+		}
+
 		// Now, we'll go through every pixel in our row and draw it.
 		for j := uint8(0); j < 8; j++ {
-			// Get the coordinates of the pixel in our display.
-			xPos := (x + j) % DisplayWidth
-			yPos := (y + i) % DisplayHeight
+			xPos := int(x)%d.width + int(j)
+			if clip {
+				if xPos >= d.width {
+					continue
+				}
+			} else {
+				xPos %= d.width
+			}
 
 			// Determine if this pixel in the sprite needs to be
 			// drawn.
 			pixel := (pixels >> (7 - j)) & 1
 			set := pixel == 1
-			drawn := d[yPos][xPos] == 1
+			drawn := d.pixels[yPos][xPos] == 1
+
+			if set && drawn {
+				flipped = true
+			}
+
+			d.pixels[yPos][xPos] ^= pixel
+		}
+	}
+
+	return flipped
+}
+
+// drawSprite16 draws a 16x16 sprite, as used by the DXY0 opcode in
+// high-resolution mode. Each of the 16 rows is encoded as two bytes (16
+// bits) rather than the single byte (8 bits) used by regular sprites. clip
+// has the same meaning as in drawSprite.
+func (d *Display) drawSprite16(sprite []byte, x, y uint8, clip bool) bool {
+	flipped := false
+
+	for i := 0; i < 16; i++ {
+		row := uint16(sprite[i*2])<<8 | uint16(sprite[i*2+1])
+
+		yPos := int(y)%d.height + i
+		if clip {
+			if yPos >= d.height {
+				continue
+			}
+		} else {
+			yPos %= d.height
+		}
+
+		for j := 0; j < 16; j++ {
+			xPos := int(x)%d.width + j
+			if clip {
+				if xPos >= d.width {
+					continue
+				}
+			} else {
+				xPos %= d.width
+			}
+
+			pixel := byte(row>>(15-j)) & 1
+			set := pixel == 1
+			drawn := d.pixels[yPos][xPos] == 1
 
 			if !set && drawn {
 				flipped = true
 			}
 
-			d[yPos][xPos] ^= pixel
+			d.pixels[yPos][xPos] ^= pixel
 		}
 	}
 
@@ -57,9 +178,61 @@ func (d *Display) drawSprite(sprite []byte, x, y uint8) bool {
 
 // clear clears the display.
 func (d *Display) clear() {
-	for y := 0; y < DisplayHeight; y++ {
-		for x := 0; x < DisplayWidth; x++ {
-			d[y][x] = 0
+	for y := range d.pixels {
+		for x := range d.pixels[y] {
+			d.pixels[y][x] = 0
+		}
+	}
+}
+
+// scrollAmount returns the number of pixels a horizontal scroll (00FB/00FC)
+// moves the display by. It scales with the display's resolution, matching
+// SCHIP/XO-CHIP behavior of scrolling 4 pixels in hi-res mode and 2 in
+// lo-res mode.
+func (d *Display) scrollAmount() int {
+	return d.width / 32
+}
+
+// scrollDown scrolls the display down by n lines, as used by the 00CN
+// opcode.
+func (d *Display) scrollDown(n int) {
+	for y := d.height - 1; y >= 0; y-- {
+		for x := 0; x < d.width; x++ {
+			if y-n >= 0 {
+				d.pixels[y][x] = d.pixels[y-n][x]
+			} else {
+				d.pixels[y][x] = 0
+			}
+		}
+	}
+}
+
+// scrollRight scrolls the display right, as used by the 00FB opcode.
+func (d *Display) scrollRight() {
+	n := d.scrollAmount()
+
+	for y := 0; y < d.height; y++ {
+		for x := d.width - 1; x >= 0; x-- {
+			if x-n >= 0 {
+				d.pixels[y][x] = d.pixels[y][x-n]
+			} else {
+				d.pixels[y][x] = 0
+			}
+		}
+	}
+}
+
+// scrollLeft scrolls the display left, as used by the 00FC opcode.
+func (d *Display) scrollLeft() {
+	n := d.scrollAmount()
+
+	for y := 0; y < d.height; y++ {
+		for x := 0; x < d.width; x++ {
+			if x+n < d.width {
+				d.pixels[y][x] = d.pixels[y][x+n]
+			} else {
+				d.pixels[y][x] = 0
+			}
 		}
 	}
 }
@@ -0,0 +1,78 @@
+// Package gif provides a chip8.Renderer that accumulates frames and
+// writes them out as an animated GIF once Close is called.
+package gif
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+
+	"github.com/wilmerpaulino/chip8"
+)
+
+const (
+	// pixelSize is the size, in GIF pixels, of each CHIP-8 display pixel.
+	pixelSize = 8
+
+	// frameDelay is the delay applied between frames, in GIF's 1/100ths
+	// of a second.
+	frameDelay = 2
+)
+
+// palette quantises CHIP-8's monochrome display down to the two colors a
+// GIF frame needs.
+var palette = color.Palette{color.Black, color.White}
+
+var _ chip8.Renderer = (*Renderer)(nil)
+
+// Renderer is a chip8.Renderer that accumulates one GIF frame per call to
+// Render, and writes them out as an animated GIF to w once Close is
+// called.
+type Renderer struct {
+	w      io.Writer
+	images []*image.Paletted
+	delays []int
+}
+
+// New creates a Renderer that writes an animated GIF to w once Close is
+// called.
+func New(w io.Writer) *Renderer {
+	return &Renderer{w: w}
+}
+
+// Render accumulates display as the next frame of the GIF.
+func (r *Renderer) Render(display chip8.Display) error {
+	width, height := display.Width(), display.Height()
+
+	img := image.NewPaletted(image.Rect(0, 0, width*pixelSize, height*pixelSize), palette)
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var idx uint8
+			if display.At(x, y) != 0 {
+				idx = 1
+			}
+
+			for dy := 0; dy < pixelSize; dy++ {
+				for dx := 0; dx < pixelSize; dx++ {
+					img.SetColorIndex(x*pixelSize+dx, y*pixelSize+dy, idx)
+				}
+			}
+		}
+	}
+
+	r.images = append(r.images, img)
+	r.delays = append(r.delays, frameDelay)
+
+	return nil
+}
+
+// Close writes the accumulated frames to w as an animated GIF. The
+// Renderer must not be used afterward.
+func (r *Renderer) Close() error {
+	return gif.EncodeAll(r.w, &gif.GIF{
+		Image: r.images,
+		Delay: r.delays,
+	})
+}
@@ -0,0 +1,30 @@
+// Package headless provides a chip8.Renderer implementation with no
+// visual output, used by tests to assert on a virtual machine's Display
+// contents.
+package headless
+
+import "github.com/wilmerpaulino/chip8"
+
+var _ chip8.Renderer = (*Renderer)(nil)
+
+// Renderer discards rendering, but records the most recently rendered
+// display so tests can assert on its contents.
+type Renderer struct {
+	display chip8.Display
+}
+
+// New creates a new headless Renderer.
+func New() *Renderer {
+	return &Renderer{}
+}
+
+// Render records display for later inspection via Display.
+func (r *Renderer) Render(display chip8.Display) error {
+	r.display = display
+	return nil
+}
+
+// Display returns the display passed to the most recent call to Render.
+func (r *Renderer) Display() chip8.Display {
+	return r.display
+}
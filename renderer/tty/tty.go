@@ -0,0 +1,72 @@
+// Package tty provides a chip8.Renderer that draws the display directly
+// to a terminal using ANSI half-block characters, so CHIP-8 programs can
+// be run in a plain shell with no graphical dependencies.
+package tty
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/wilmerpaulino/chip8"
+)
+
+const (
+	// halfBlock is "▀", used to draw two vertical pixels per terminal
+	// cell: its foreground color is the top pixel, its background color
+	// is the bottom pixel.
+	halfBlock = "▀"
+
+	// onColor and offColor quantise CHIP-8's monochrome pixels down to
+	// the ANSI 256-color palette.
+	onColor  = 15 // bright white: a set pixel.
+	offColor = 0  // black: an unset pixel.
+)
+
+var _ chip8.Renderer = (*Renderer)(nil)
+
+// Renderer draws the display to w using ANSI half-block characters.
+type Renderer struct {
+	w io.Writer
+}
+
+// New creates a Renderer that draws to w, typically os.Stdout.
+func New(w io.Writer) *Renderer {
+	return &Renderer{w: w}
+}
+
+// Render redraws the entire display, moving the cursor back to the top
+// left first so each frame overwrites the last.
+func (r *Renderer) Render(display chip8.Display) error {
+	width, height := display.Width(), display.Height()
+
+	var b strings.Builder
+	b.WriteString("\x1b[H") // Move the cursor to the top left.
+
+	for y := 0; y < height; y += 2 {
+		for x := 0; x < width; x++ {
+			top := colorAt(display, x, y)
+
+			bottom := offColor
+			if y+1 < height {
+				bottom = colorAt(display, x, y+1)
+			}
+
+			fmt.Fprintf(&b, "\x1b[38;5;%dm\x1b[48;5;%dm%s", top, bottom, halfBlock)
+		}
+
+		b.WriteString("\x1b[0m\n")
+	}
+
+	_, err := io.WriteString(r.w, b.String())
+	return err
+}
+
+// colorAt quantises the pixel at (x, y) to onColor or offColor.
+func colorAt(display chip8.Display, x, y int) int {
+	if display.At(x, y) != 0 {
+		return onColor
+	}
+
+	return offColor
+}
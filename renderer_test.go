@@ -0,0 +1,54 @@
+package chip8
+
+import "testing"
+
+// TestDrawSpriteClipWrapsStartPosition regresses a bug where a sprite drawn
+// with a start coordinate past the display's edge (as VX/VY almost always
+// is, since they're arbitrary byte values) was clipped out of existence
+// entirely instead of having its start position wrapped onto the screen
+// before the overflow was clipped.
+func TestDrawSpriteClipWrapsStartPosition(t *testing.T) {
+	d := newDisplay(DisplayWidth, DisplayHeight)
+
+	sprite := []byte{0xff} // one row, all 8 pixels set
+	d.drawSprite(sprite, 70, 0, true)
+
+	// 70 wraps to column 6 on a 64-wide display, so columns 6-13 should
+	// be set and nothing else.
+	for x := 0; x < DisplayWidth; x++ {
+		want := byte(0)
+		if x >= 6 && x < 14 {
+			want = 1
+		}
+
+		if got := d.pixels[0][x]; got != want {
+			t.Errorf("pixel (%d, 0) = %d, want %d", x, got, want)
+		}
+	}
+}
+
+// TestDrawSprite16ClipWrapsStartPosition is TestDrawSpriteClipWrapsStartPosition
+// for the 16x16 SCHIP/XO-CHIP sprite path.
+func TestDrawSprite16ClipWrapsStartPosition(t *testing.T) {
+	d := newDisplay(HiResDisplayWidth, HiResDisplayHeight)
+
+	sprite := make([]byte, 32)
+	for i := range sprite {
+		sprite[i] = 0xff
+	}
+
+	d.drawSprite16(sprite, 140, 0, true)
+
+	// 140 wraps to column 12 on a 128-wide display, so columns 12-27
+	// should be set on row 0 and nothing else.
+	for x := 0; x < HiResDisplayWidth; x++ {
+		want := byte(0)
+		if x >= 12 && x < 28 {
+			want = 1
+		}
+
+		if got := d.pixels[0][x]; got != want {
+			t.Errorf("pixel (%d, 0) = %d, want %d", x, got, want)
+		}
+	}
+}
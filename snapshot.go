@@ -0,0 +1,175 @@
+package chip8
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"sync/atomic"
+)
+
+// snapshotMagic identifies the start of a Snapshot's binary encoding.
+const snapshotMagic = "CH8S"
+
+// snapshotVersion is incremented whenever the Snapshot binary format
+// changes in a way that breaks compatibility with older snapshots.
+const snapshotVersion = 2
+
+// Snapshot serialises the virtual machine's full state — memory,
+// registers, stack, timers, keys, display, RNG state, and mode — into a
+// versioned binary format suitable for save-states and, combined with a
+// Recorder's trace, deterministic replay via Replay.
+//
+// Snapshot locks against run via vm.stateMu, the same mutex Debugger.State
+// uses, so it's safe to call on a running virtual machine — the snapshot
+// never observes a step half-applied.
+func (vm *VirtualMachine) Snapshot() ([]byte, error) {
+	vm.stateMu.Lock()
+	defer vm.stateMu.Unlock()
+
+	var buf bytes.Buffer
+
+	buf.WriteString(snapshotMagic)
+
+	fields := []interface{}{
+		uint32(snapshotVersion),
+		vm.memory,
+		vm.v,
+		vm.i,
+		vm.pc,
+		vm.stack,
+		vm.sp,
+		atomic.LoadInt32(&vm.delayTimer),
+		atomic.LoadInt32(&vm.soundTimer),
+		int32(vm.mode),
+		vm.rngSeed,
+		vm.rngDraws,
+		vm.audioPattern,
+		vm.audioPitch,
+		uint16(vm.display.Width()),
+		uint16(vm.display.Height()),
+	}
+
+	for _, f := range fields {
+		if err := binary.Write(&buf, binary.BigEndian, f); err != nil {
+			return nil, fmt.Errorf("chip8: failed writing snapshot: %v", err)
+		}
+	}
+
+	for _, row := range vm.display.pixels {
+		if err := binary.Write(&buf, binary.BigEndian, row); err != nil {
+			return nil, fmt.Errorf("chip8: failed writing snapshot display: %v", err)
+		}
+	}
+
+	var keys [numKeys]byte
+	for i := 0; i < numKeys; i++ {
+		if vm.input.IsPressed(uint8(i)) {
+			keys[i] = 1
+		}
+	}
+	if err := binary.Write(&buf, binary.BigEndian, keys); err != nil {
+		return nil, fmt.Errorf("chip8: failed writing snapshot keys: %v", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Restore replaces the virtual machine's state with a Snapshot previously
+// produced by Snapshot. The virtual machine must not be running.
+func (vm *VirtualMachine) Restore(data []byte) error {
+	r := bytes.NewReader(data)
+
+	magic := make([]byte, len(snapshotMagic))
+	if _, err := io.ReadFull(r, magic); err != nil || string(magic) != snapshotMagic {
+		return errors.New("chip8: not a chip8 snapshot")
+	}
+
+	var version uint32
+	if err := binary.Read(r, binary.BigEndian, &version); err != nil {
+		return fmt.Errorf("chip8: failed reading snapshot version: %v", err)
+	}
+	if version != snapshotVersion {
+		return fmt.Errorf("chip8: unsupported snapshot version %d", version)
+	}
+
+	var (
+		memory                 [memorySize]byte
+		v                      [numRegisters]byte
+		i, pc                  uint16
+		stack                  [numFrames]uint16
+		sp                     byte
+		delayTimer, soundTimer int32
+		mode                   int32
+		rngSeed                int64
+		rngDraws               uint64
+		audioPattern           [16]byte
+		audioPitch             byte
+		width, height          uint16
+	)
+
+	fields := []interface{}{
+		&memory, &v, &i, &pc, &stack, &sp, &delayTimer, &soundTimer,
+		&mode, &rngSeed, &rngDraws, &audioPattern, &audioPitch, &width, &height,
+	}
+	for _, f := range fields {
+		if err := binary.Read(r, binary.BigEndian, f); err != nil {
+			return fmt.Errorf("chip8: failed reading snapshot: %v", err)
+		}
+	}
+
+	display := newDisplay(int(width), int(height))
+	for y := range display.pixels {
+		if err := binary.Read(r, binary.BigEndian, display.pixels[y]); err != nil {
+			return fmt.Errorf("chip8: failed reading snapshot display: %v", err)
+		}
+	}
+
+	var keys [numKeys]byte
+	if err := binary.Read(r, binary.BigEndian, &keys); err != nil {
+		return fmt.Errorf("chip8: failed reading snapshot keys: %v", err)
+	}
+
+	vm.memory = memory
+	vm.v = v
+	vm.i = i
+	vm.pc = pc
+	vm.stack = stack
+	vm.sp = sp
+	atomic.StoreInt32(&vm.delayTimer, delayTimer)
+	atomic.StoreInt32(&vm.soundTimer, soundTimer)
+	vm.mode = Mode(mode)
+	vm.display = display
+
+	// math/rand.Rand doesn't expose its internal state, so rather than
+	// serialising it directly, we persist the seed alongside the number
+	// of values drawn from it and fast-forward a freshly seeded Rand by
+	// that many draws. This reproduces rng's exact state, since it's
+	// otherwise only ever advanced by the 0xC000 opcode.
+	vm.rngSeed = rngSeed
+	vm.rngDraws = rngDraws
+	vm.rng = rand.New(rand.NewSource(rngSeed))
+	for n := uint64(0); n < rngDraws; n++ {
+		vm.rng.Intn(255)
+	}
+
+	vm.audioPattern = audioPattern
+	vm.audioPitch = audioPitch
+	if err := vm.audio.LoadPattern(vm.audioPattern, vm.audioPitch); err != nil {
+		return fmt.Errorf("chip8: failed restoring audio pattern: %v", err)
+	}
+
+	if ki, ok := vm.input.(KeyInput); ok {
+		for idx := 0; idx < numKeys; idx++ {
+			if keys[idx] != 0 {
+				ki.PressKey(uint8(idx))
+			} else {
+				ki.ReleaseKey(uint8(idx))
+			}
+		}
+	}
+
+	return vm.renderer.Render(vm.display)
+}
@@ -2,9 +2,12 @@ package chip8
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"math/rand"
+	"os"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -29,9 +32,25 @@ const (
 	// numKeys is the number of supported keys of the virtual machine.
 	numKeys = 16
 
-	// defaultClockSpeed is the default clock speed of the virtual machine's
-	// CPU in hertz.
-	defaultClockSpeed = time.Duration(60)
+	// numRPLFlags is the number of persistent RPL user flags exposed by
+	// the FX75/FX85 opcodes, matching the 8 flags of the HP48 calculators
+	// SCHIP was originally designed for.
+	numRPLFlags = 8
+
+	// bigFontOffset is the memory offset at which the large (8x10) hex
+	// font used by the FX30 opcode is stored, right after the regular
+	// font (16 characters of 5 bytes each).
+	bigFontOffset = 16 * 5
+
+	// timerSpeed is the fixed speed, in hertz, at which the delay and
+	// sound timers are decremented. This matches real CHIP-8 hardware and
+	// is independent of the CPU clock speed.
+	timerSpeed = time.Duration(60)
+
+	// defaultClockSpeed is the default CPU clock speed of the virtual
+	// machine, in instructions per second, used if Options.ClockSpeed is
+	// left unset.
+	defaultClockSpeed = time.Duration(700)
 )
 
 var (
@@ -54,8 +73,116 @@ var (
 		0xF0, 0x80, 0xF0, 0x80, 0xF0,
 		0xF0, 0x80, 0xF0, 0x80, 0x80,
 	}
+
+	// bigFont is the large (8x10) hex font used by SCHIP/XO-CHIP's FX30
+	// opcode, addressed at bigFontOffset.
+	bigFont = []byte{
+		0x3C, 0x7E, 0xE7, 0xC3, 0xC3, 0xC3, 0xC3, 0xE7, 0x7E, 0x3C, // 0
+		0x18, 0x38, 0x58, 0x18, 0x18, 0x18, 0x18, 0x18, 0x18, 0x3C, // 1
+		0x7E, 0xFF, 0x03, 0x03, 0x07, 0x7E, 0xC0, 0xC0, 0xC0, 0xFF, // 2
+		0x7E, 0xFF, 0x03, 0x03, 0x3E, 0x03, 0x03, 0x03, 0xFF, 0x7E, // 3
+		0x06, 0x0E, 0x1E, 0x36, 0x66, 0xC6, 0xFF, 0xFF, 0x06, 0x06, // 4
+		0xFF, 0xFF, 0xC0, 0xC0, 0xFE, 0xFF, 0x03, 0x03, 0xFF, 0xFE, // 5
+		0x7E, 0xFF, 0xC0, 0xC0, 0xFE, 0xFF, 0xC3, 0xC3, 0xFF, 0x7E, // 6
+		0xFF, 0xFF, 0x03, 0x06, 0x0C, 0x18, 0x30, 0x30, 0x30, 0x30, // 7
+		0x7E, 0xFF, 0xC3, 0xC3, 0x7E, 0x7E, 0xC3, 0xC3, 0xFF, 0x7E, // 8
+		0x7E, 0xFF, 0xC3, 0xC3, 0xFF, 0x7F, 0x03, 0x03, 0xFF, 0x7E, // 9
+		0x18, 0x3C, 0x66, 0xC3, 0xC3, 0xFF, 0xFF, 0xC3, 0xC3, 0xC3, // A
+		0xFE, 0xFF, 0xC3, 0xC3, 0xFE, 0xFE, 0xC3, 0xC3, 0xFF, 0xFE, // B
+		0x3C, 0x7E, 0xE7, 0xC0, 0xC0, 0xC0, 0xC0, 0xE7, 0x7E, 0x3C, // C
+		0xFC, 0xFE, 0xC7, 0xC3, 0xC3, 0xC3, 0xC3, 0xC7, 0xFE, 0xFC, // D
+		0xFF, 0xFF, 0xC0, 0xC0, 0xFC, 0xFC, 0xC0, 0xC0, 0xFF, 0xFF, // E
+		0xFF, 0xFF, 0xC0, 0xC0, 0xFC, 0xFC, 0xC0, 0xC0, 0xC0, 0xC0, // F
+	}
+)
+
+// Mode represents the variant of the CHIP-8 specification emulated by a
+// VirtualMachine. It controls which of the several conflicting behaviors
+// ("quirks") earlier/later interpreters settled on is used, and whether
+// DXY0 draws a 16x16 sprite. The other extended SCHIP/XO-CHIP opcodes are
+// executed in every mode, ModeVIP included.
+type Mode int
+
+const (
+	// ModeVIP emulates the original COSMAC VIP interpreter. Its display
+	// starts at 64x32, but 00FF still switches it to 128x64.
+	ModeVIP Mode = iota
+
+	// ModeSuperChip emulates SUPER-CHIP (SCHIP), adding the 128x64
+	// high-resolution display and its associated opcodes.
+	ModeSuperChip
+
+	// ModeXOChip emulates XO-CHIP, a further extension of SCHIP adding a
+	// programmable audio pattern on top of its opcodes.
+	ModeXOChip
 )
 
+// ClippingMode overrides whether drawSprite clips sprites at the display
+// edges instead of wrapping them to the opposite edge, as set by
+// Quirks.Clipping.
+type ClippingMode int
+
+const (
+	// ClippingDefault derives clipping behavior from Mode: wrapping for
+	// ModeVIP, clipping for ModeSuperChip/ModeXOChip.
+	ClippingDefault ClippingMode = iota
+
+	// ClippingWrap always wraps sprites around to the opposite edge.
+	ClippingWrap
+
+	// ClippingClip always clips sprites at the display edges.
+	ClippingClip
+)
+
+// Quirks overrides individual interpreter behaviors that differ between
+// CHIP-8 implementations, beyond what Mode alone determines. Unlike Mode,
+// which picks a whole interpreter family, Quirks lets callers override a
+// single behavior in isolation.
+type Quirks struct {
+	// Clipping controls whether sprites drawn past the display's edges
+	// clip or wrap. Defaults to ClippingDefault, which derives the
+	// behavior from Mode.
+	Clipping ClippingMode
+}
+
+// Options holds the configuration used to create a VirtualMachine via
+// NewWithOptions.
+type Options struct {
+	// Mode selects the CHIP-8 variant to emulate. Defaults to ModeVIP.
+	Mode Mode
+
+	// RPLFlagsPath is the file the FX75/FX85 opcodes persist their RPL
+	// user flags to. If empty, RPL flags are kept in memory only and do
+	// not survive across runs.
+	RPLFlagsPath string
+
+	// InputSource provides the virtual machine's keyboard input. If nil,
+	// the default in-memory implementation returned by NewInputSource is
+	// used, and PressKey/ReleaseKey can be used to drive it.
+	InputSource InputSource
+
+	// ClockSpeed is the CPU clock speed, in instructions per second. Real
+	// CHIP-8 hardware has no fixed speed; most ROMs target somewhere
+	// between 500 and 700. Defaults to defaultClockSpeed if zero.
+	//
+	// NOTE: this is independent from the 60 Hz delay/sound timers, which
+	// always run at their own fixed rate.
+	ClockSpeed int
+
+	// RandSeed seeds the virtual machine's RNG, used by the 0xC000
+	// opcode. Defaults to a time-based seed if zero. Set this to a fixed
+	// value for deterministic replay.
+	RandSeed int64
+
+	// Audio plays the sound timer's beep and, on XO-CHIP, the F002/FX3A
+	// audio pattern. If nil, sound is discarded.
+	Audio Audio
+
+	// Quirks overrides individual interpreter behaviors that otherwise
+	// default based on Mode.
+	Quirks Quirks
+}
+
 // VirtualMachine emulates the CHIP-8 virtual machine.
 type VirtualMachine struct {
 	started int32
@@ -96,37 +223,144 @@ type VirtualMachine struct {
 	renderer Renderer
 
 	// delayTimer is the virtual machine's delay timer used for timing
-	// events.
-	delayTimer byte
+	// events. It is decremented by a dedicated 60 Hz goroutine, separate
+	// from the CPU clock, so it's accessed atomically.
+	delayTimer int32
 
 	// soundTimer is the virtual machine's sound timer used for sound
-	// effects. When its value is non-zero, a beeping sound is made.
-	soundTimer byte
-
-	// keys holds the current state for all supported keys. If the key is
-	// pressed, then the state is true. Otherwise, it is false.
-	keys [numKeys]bool
-
-	// clock is a ticker that represents the clock of the virtual machine.
-	// The default clock speed is 60 Hz.
-	clock <-chan time.Time
+	// effects. When its value is non-zero, a beeping sound is made. Like
+	// delayTimer, it is decremented by a dedicated 60 Hz goroutine, so
+	// it's accessed atomically.
+	soundTimer int32
+
+	// soundPlaying tracks whether audio.Start has been called for the
+	// current run of a nonzero soundTimer, so tickTimers can call
+	// Start/Stop on the edges of soundTimer crossing zero rather than on
+	// every tick it stays nonzero. Accessed atomically, alongside
+	// soundTimer.
+	soundPlaying int32
+
+	// audio plays the sound timer's beep and the XO-CHIP audio pattern.
+	audio Audio
+
+	// audioPattern and audioPitch are the most recently loaded XO-CHIP
+	// audio pattern and pitch, set by the F002/FX3A opcodes. audioPitch
+	// defaults to 64, matching the XO-CHIP default playback rate of
+	// 4000 Hz.
+	audioPattern [16]byte
+	audioPitch   byte
+
+	// input provides the virtual machine's keyboard input.
+	input InputSource
+
+	// mode is the CHIP-8 variant this virtual machine emulates.
+	mode Mode
+
+	// quirks overrides individual interpreter behaviors that otherwise
+	// default based on mode.
+	quirks Quirks
+
+	// rpl holds the persistent RPL user flags read and written by the
+	// FX75/FX85 opcodes.
+	rpl [numRPLFlags]byte
+
+	// rplPath is the file the RPL user flags are persisted to. If empty,
+	// they are kept in memory only.
+	rplPath string
+
+	// rng generates the random numbers used by the 0xC000 opcode. It is
+	// created once, at startup, rather than reseeded on every use, so
+	// that a fixed Options.RandSeed yields deterministic execution.
+	rng *rand.Rand
+
+	// rngSeed is the seed rng was created with, and rngDraws is the
+	// number of random numbers drawn from it so far. Together they let
+	// Snapshot/Restore reproduce rng's exact state, since math/rand
+	// doesn't expose it directly.
+	rngSeed  int64
+	rngDraws uint64
+
+	// recorder, if attached via NewRecorder, is sampled by run after
+	// every executed instruction to build an input trace for Replay.
+	recorder *Recorder
+
+	// ips is the configured CPU clock speed, in instructions per second,
+	// recorded so Replay can reconstruct how many instructions
+	// execute per timer tick.
+	ips int
+
+	// cpuClock is a ticker that drives instruction execution. Its rate is
+	// Options.ClockSpeed, which defaults to defaultClockSpeed.
+	cpuClock <-chan time.Time
+
+	// timerClock is a ticker, fixed at timerSpeed (60 Hz), that drives
+	// delayTimer/soundTimer decrements independently of cpuClock.
+	timerClock <-chan time.Time
+
+	// debugger, if attached via NewDebugger, is consulted by run between
+	// every executed instruction.
+	debugger *Debugger
+
+	// stateMu guards the fields read by Snapshot and Debugger.State
+	// (memory, v, i, pc, stack, sp, display) against run's CPU goroutine,
+	// which holds it for the duration of each step. It's released while a
+	// step is blocked on FX0A, so a snapshot taken at an unanswered key
+	// prompt doesn't have to wait for a key press.
+	stateMu sync.Mutex
 
 	quit chan struct{}
 	wg   sync.WaitGroup
 }
 
-// New creates a new CHIP-8 virtual machine.
+// New creates a new CHIP-8 virtual machine emulating the original VIP
+// interpreter. Use NewWithOptions to emulate SCHIP/XO-CHIP instead.
 func New(r Renderer) *VirtualMachine {
-	vm := &VirtualMachine{
-		pc:    memoryOffset,
-		clock: time.Tick(time.Second / defaultClockSpeed),
-		quit:  make(chan struct{}),
+	return NewWithOptions(r, Options{})
+}
+
+// NewWithOptions creates a new CHIP-8 virtual machine using the given
+// options.
+func NewWithOptions(r Renderer, opts Options) *VirtualMachine {
+	input := opts.InputSource
+	if input == nil {
+		input = NewInputSource()
+	}
+
+	audio := opts.Audio
+	if audio == nil {
+		audio = silentAudio{}
+	}
+
+	clockSpeed := time.Duration(opts.ClockSpeed)
+	if clockSpeed <= 0 {
+		clockSpeed = defaultClockSpeed
 	}
 
-	for i := 0; i < len(font); i++ {
-		vm.memory[i] = font[i]
+	seed := opts.RandSeed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
 	}
 
+	vm := &VirtualMachine{
+		pc:         memoryOffset,
+		ips:        int(clockSpeed),
+		cpuClock:   time.Tick(time.Second / clockSpeed),
+		timerClock: time.Tick(time.Second / timerSpeed),
+		quit:       make(chan struct{}),
+		mode:       opts.Mode,
+		quirks:     opts.Quirks,
+		rplPath:    opts.RPLFlagsPath,
+		input:      input,
+		rng:        rand.New(rand.NewSource(seed)),
+		rngSeed:    seed,
+		audio:      audio,
+		audioPitch: 64,
+		display:    newDisplay(DisplayWidth, DisplayHeight),
+	}
+
+	copy(vm.memory[:len(font)], font)
+	copy(vm.memory[bigFontOffset:], bigFont)
+
 	vm.renderer = r
 
 	return vm
@@ -154,8 +388,9 @@ func (vm *VirtualMachine) Start() {
 		return
 	}
 
-	vm.wg.Add(1)
+	vm.wg.Add(2)
 	go vm.run()
+	go vm.runTimers()
 }
 
 // Stop stops executing the virtual machine.
@@ -180,9 +415,8 @@ func (vm *VirtualMachine) Reset() {
 		vm.memory[i] = 0
 	}
 
-	for i := 0; i < len(font); i++ {
-		vm.memory[i] = font[i]
-	}
+	copy(vm.memory[:len(font)], font)
+	copy(vm.memory[bigFontOffset:], bigFont)
 
 	for i := 0; i < numRegisters; i++ {
 		vm.v[i] = 0
@@ -192,20 +426,22 @@ func (vm *VirtualMachine) Reset() {
 	vm.pc = memoryOffset
 	vm.sp = 0
 
-	vm.display.clear()
+	vm.display = newDisplay(DisplayWidth, DisplayHeight)
 	vm.renderer.Render(vm.display)
 
-	vm.delayTimer = 0
-	vm.soundTimer = 0
+	atomic.StoreInt32(&vm.delayTimer, 0)
+	atomic.StoreInt32(&vm.soundTimer, 0)
 
-	for i := 0; i < numKeys; i++ {
-		vm.keys[i] = false
+	if ki, ok := vm.input.(KeyInput); ok {
+		for i := 0; i < numKeys; i++ {
+			ki.ReleaseKey(uint8(i))
+		}
 	}
 
 	vm.quit = make(chan struct{})
 }
 
-// run executes the opcode at every step of the virtual machine's execution.
+// run executes the opcode at every tick of the CPU clock.
 //
 // NOTE: This MUST be run in a goroutine.
 func (vm *VirtualMachine) run() {
@@ -214,17 +450,74 @@ func (vm *VirtualMachine) run() {
 out:
 	for {
 		select {
-		case <-vm.clock:
-			if err := vm.step(); err != nil {
+		case <-vm.cpuClock:
+			if vm.debugger != nil && !vm.debugger.awaitTurn(vm.quit) {
+				break out
+			}
+
+			vm.stateMu.Lock()
+			err := vm.step()
+			vm.stateMu.Unlock()
+			if err != nil {
+				if err == errExit {
+					break out
+				}
+
 				err = fmt.Errorf("chip8: %v", err)
 				panic(err)
 			}
+
+			if vm.debugger != nil {
+				vm.debugger.afterStep()
+			}
+
+			if vm.recorder != nil {
+				vm.recorder.sample()
+			}
 		case <-vm.quit:
 			break out
 		}
 	}
 }
 
+// runTimers decrements the delay/sound timers at every tick of the 60 Hz
+// timer clock, independently of the CPU clock driving run.
+//
+// NOTE: This MUST be run in a goroutine.
+func (vm *VirtualMachine) runTimers() {
+	defer vm.wg.Done()
+
+	for {
+		select {
+		case <-vm.timerClock:
+			vm.tickTimers()
+		case <-vm.quit:
+			return
+		}
+	}
+}
+
+// tickTimers decrements the delay/sound timers by one step. audio.Start
+// and audio.Stop are called only on the edges of soundTimer crossing zero,
+// not on every tick it stays nonzero. It's called once per timer tick by
+// runTimers, and directly by Replay, which drives timers deterministically
+// rather than from the wall clock.
+func (vm *VirtualMachine) tickTimers() {
+	if atomic.LoadInt32(&vm.delayTimer) > 0 {
+		atomic.AddInt32(&vm.delayTimer, -1)
+	}
+
+	if atomic.LoadInt32(&vm.soundTimer) > 0 {
+		if atomic.CompareAndSwapInt32(&vm.soundPlaying, 0, 1) {
+			vm.audio.Start()
+		}
+
+		atomic.AddInt32(&vm.soundTimer, -1)
+	} else if atomic.CompareAndSwapInt32(&vm.soundPlaying, 1, 0) {
+		vm.audio.Stop()
+	}
+}
+
 // step steps through the next opcode.
 func (vm *VirtualMachine) step() error {
 	op, err := vm.decodeNextOpcode()
@@ -233,21 +526,21 @@ func (vm *VirtualMachine) step() error {
 	}
 
 	if err := vm.execute(op); err != nil {
-		return fmt.Errorf("failed executing opcode %v: %v", op, err)
-	}
-
-	if vm.delayTimer > 0 {
-		vm.delayTimer--
-	}
+		if err == errExit {
+			return err
+		}
 
-	if vm.soundTimer > 0 {
-		vm.renderer.Beep()
-		vm.soundTimer--
+		return fmt.Errorf("failed executing opcode %v: %v", op, err)
 	}
 
 	return nil
 }
 
+// errExit is returned by execute when the 00FD opcode (SCHIP/XO-CHIP's
+// "exit interpreter") is run. It is handled internally by run and never
+// surfaces to callers.
+var errExit = errors.New("chip8: program requested interpreter exit")
+
 // decodeNextOpcode decodes the next opcode available.
 func (vm *VirtualMachine) decodeNextOpcode() (opcode, error) {
 	if vm.pc+1 > memorySize {
@@ -268,12 +561,12 @@ func (vm *VirtualMachine) decodeNextOpcode() (opcode, error) {
 func (vm *VirtualMachine) execute(op opcode) error {
 	switch op & 0xf000 {
 	case 0x0000:
-		switch op {
-		case 0x00e0:
+		switch {
+		case op == 0x00e0:
 			// Clear the screen.
 			vm.display.clear()
 			vm.renderer.Render(vm.display)
-		case 0x00ee:
+		case op == 0x00ee:
 			// Return from a subroutine.
 			if vm.sp == 0 {
 				return errors.New("stack underflow")
@@ -281,6 +574,32 @@ func (vm *VirtualMachine) execute(op opcode) error {
 
 			vm.sp--
 			vm.pc = vm.stack[vm.sp]
+		case op&0xfff0 == 0x00c0:
+			// Scroll the display down by the nibble constant encoded
+			// in the opcode, in pixels. (SCHIP/XO-CHIP)
+			vm.display.scrollDown(int(op.NibbleConstant()))
+			vm.renderer.Render(vm.display)
+		case op == 0x00fb:
+			// Scroll the display right by 4 pixels in hi-res mode, 2
+			// in lo-res mode. (SCHIP/XO-CHIP)
+			vm.display.scrollRight()
+			vm.renderer.Render(vm.display)
+		case op == 0x00fc:
+			// Scroll the display left by 4 pixels in hi-res mode, 2 in
+			// lo-res mode. (SCHIP/XO-CHIP)
+			vm.display.scrollLeft()
+			vm.renderer.Render(vm.display)
+		case op == 0x00fd:
+			// Exit the interpreter. (SCHIP/XO-CHIP)
+			return errExit
+		case op == 0x00fe:
+			// Switch to low-resolution (64x32) mode. (SCHIP/XO-CHIP)
+			vm.display = newDisplay(DisplayWidth, DisplayHeight)
+			vm.renderer.Render(vm.display)
+		case op == 0x00ff:
+			// Switch to high-resolution (128x64) mode. (SCHIP/XO-CHIP)
+			vm.display = newDisplay(HiResDisplayWidth, HiResDisplayHeight)
+			vm.renderer.Render(vm.display)
 		default:
 			return ErrUnknownOpcode
 		}
@@ -394,14 +713,26 @@ func (vm *VirtualMachine) execute(op opcode) error {
 			}
 		case 0x0006:
 			// Shift VY right by one and copy it to VX. Set VF to
-			// the least significant bit of VY before the shft.
+			// the least significant bit of VY before the shift.
+			//
+			// NOTE: SCHIP/XO-CHIP shift VX in place instead, ignoring
+			// VY. See shiftInPlace.
 			x := op.RegisterIndex(true)
 			y := op.RegisterIndex(false)
 
-			lsb := vm.v[y] & 1
+			src := vm.v[y]
+			if vm.shiftInPlace() {
+				src = vm.v[x]
+			}
+
+			lsb := src & 1
+			shifted := src >> 1
+
+			if !vm.shiftInPlace() {
+				vm.v[y] = shifted
+			}
+			vm.v[x] = shifted
 			vm.v[0xf] = lsb
-			vm.v[y] >>= 1
-			vm.v[x] = vm.v[y]
 		case 0x0007:
 			// Set VX to VX subtracted from VY. Set VF to 0 if there
 			// is a borrow, otherwise set it to 1.
@@ -417,14 +748,26 @@ func (vm *VirtualMachine) execute(op opcode) error {
 			}
 		case 0x000e:
 			// Shift VY left by one and copy it to VX. Set VF to the
-			// most significant bit of VY before the shft.
+			// most significant bit of VY before the shift.
+			//
+			// NOTE: SCHIP/XO-CHIP shift VX in place instead, ignoring
+			// VY. See shiftInPlace.
 			x := op.RegisterIndex(true)
 			y := op.RegisterIndex(false)
 
-			msb := vm.v[y] >> 7
+			src := vm.v[y]
+			if vm.shiftInPlace() {
+				src = vm.v[x]
+			}
+
+			msb := src >> 7
+			shifted := src << 1
+
+			if !vm.shiftInPlace() {
+				vm.v[y] = shifted
+			}
+			vm.v[x] = shifted
 			vm.v[0xf] = msb
-			vm.v[y] <<= 1
-			vm.v[x] = vm.v[y]
 		default:
 			return ErrUnknownOpcode
 		}
@@ -452,7 +795,8 @@ func (vm *VirtualMachine) execute(op opcode) error {
 		// Set VX to a bitwise and operation between a random number and
 		// the byte constant encoded in the opcode.
 		x := op.RegisterIndex(true)
-		r := rand.New(rand.NewSource(time.Now().UnixNano())).Intn(255)
+		r := vm.rng.Intn(255)
+		vm.rngDraws++
 		val := op.ByteConstant()
 
 		vm.v[x] = byte(r) & val
@@ -464,13 +808,21 @@ func (vm *VirtualMachine) execute(op opcode) error {
 		// address register should not change after. Set VF to 1 if any
 		// screen pixels are flipped from set to unset when the sprite
 		// is drawn, otherwise set it to 0.
+		//
+		// NOTE: on SCHIP/XO-CHIP, a height of 0 instead draws a 16x16
+		// sprite, encoded as 32 bytes (DXY0).
 		x := op.RegisterIndex(true)
 		y := op.RegisterIndex(false)
 		height := op.NibbleConstant()
 
-		flipped := vm.display.drawSprite(
-			vm.memory[vm.i:vm.i+uint16(height)], vm.v[x], vm.v[y],
-		)
+		var flipped bool
+		if height == 0 && vm.mode != ModeVIP {
+			flipped = vm.display.drawSprite16(vm.memory[vm.i:vm.i+32], vm.v[x], vm.v[y], vm.clips())
+		} else {
+			flipped = vm.display.drawSprite(
+				vm.memory[vm.i:vm.i+uint16(height)], vm.v[x], vm.v[y], vm.clips(),
+			)
+		}
 
 		if flipped {
 			vm.v[0xf] = 1
@@ -486,7 +838,7 @@ func (vm *VirtualMachine) execute(op opcode) error {
 			// pressed.
 			x := op.RegisterIndex(true)
 
-			if vm.keys[vm.v[x]] {
+			if vm.input.IsPressed(vm.v[x]) {
 				vm.pc += 2
 			}
 		case 0x00a1:
@@ -494,7 +846,7 @@ func (vm *VirtualMachine) execute(op opcode) error {
 			// not pressed.
 			x := op.RegisterIndex(true)
 
-			if !vm.keys[vm.v[x]] {
+			if !vm.input.IsPressed(vm.v[x]) {
 				vm.pc += 2
 			}
 		default:
@@ -502,24 +854,43 @@ func (vm *VirtualMachine) execute(op opcode) error {
 		}
 	case 0xf000:
 		switch op & 0x00ff {
+		case 0x0002:
+			// Load the 16-byte (128-sample) audio pattern from memory
+			// starting at I. (XO-CHIP)
+			copy(vm.audioPattern[:], vm.memory[vm.i:vm.i+16])
+
+			if err := vm.audio.LoadPattern(vm.audioPattern, vm.audioPitch); err != nil {
+				return fmt.Errorf("failed loading audio pattern: %v", err)
+			}
 		case 0x0007:
 			// Set VX to the delay timer.
 			x := op.RegisterIndex(true)
 
-			vm.v[x] = vm.delayTimer
+			vm.v[x] = byte(atomic.LoadInt32(&vm.delayTimer))
 		case 0x000a:
-			// TODO: Wait for a key press, and then store it in VX.
-			panic("unimplemented opcode")
+			// Block until a key is pressed, then store it in VX.
+			x := op.RegisterIndex(true)
+
+			key, err := vm.waitKey()
+			if err != nil {
+				if errors.Is(err, context.Canceled) {
+					return errExit
+				}
+
+				return fmt.Errorf("failed waiting for key press: %v", err)
+			}
+
+			vm.v[x] = key
 		case 0x0015:
 			// Set the delay timer to VX.
 			x := op.RegisterIndex(true)
 
-			vm.delayTimer = vm.v[x]
+			atomic.StoreInt32(&vm.delayTimer, int32(vm.v[x]))
 		case 0x0018:
 			// Set the sound timer to VX.
 			x := op.RegisterIndex(true)
 
-			vm.soundTimer = vm.v[x]
+			atomic.StoreInt32(&vm.soundTimer, int32(vm.v[x]))
 		case 0x001e:
 			// Add VX to the address register.
 			x := op.RegisterIndex(true)
@@ -532,6 +903,20 @@ func (vm *VirtualMachine) execute(op opcode) error {
 			x := op.RegisterIndex(true)
 
 			vm.i = uint16(vm.v[x]) * 5
+		case 0x0030:
+			// Set the address register to the location of the large
+			// (8x10) sprite for the digit in VX. (SCHIP/XO-CHIP)
+			x := op.RegisterIndex(true)
+
+			vm.i = uint16(bigFontOffset) + uint16(vm.v[x])*10
+		case 0x003a:
+			// Set the audio pattern's playback pitch to VX. (XO-CHIP)
+			x := op.RegisterIndex(true)
+
+			vm.audioPitch = vm.v[x]
+			if err := vm.audio.LoadPattern(vm.audioPattern, vm.audioPitch); err != nil {
+				return fmt.Errorf("failed setting audio pitch: %v", err)
+			}
 		case 0x0033:
 			// Store the binary-coded decimal representation of VX,
 			// with the most significant of three digits at the
@@ -544,24 +929,58 @@ func (vm *VirtualMachine) execute(op opcode) error {
 			vm.memory[vm.i+1] = (bcd / 10) % 10
 			vm.memory[vm.i+2] = bcd % 10
 		case 0x0055:
-			// Store the values from registers V0-VX in memory
-			// starting at address I. I is increased by 1 for each
-			// value written.
+			// Store the values from registers V0-VX, inclusive, in
+			// memory starting at address I.
+			//
+			// NOTE: on VIP/XO-CHIP, I is left advanced by the number
+			// of values written; SCHIP leaves it unchanged. See
+			// incrementIOnLoadStore.
 			x := op.RegisterIndex(true)
 
-			for i := uint16(0); i < x; i++ {
-				vm.memory[vm.i] = vm.v[i]
-				vm.i++
+			for i := uint16(0); i <= x; i++ {
+				vm.memory[vm.i+i] = vm.v[i]
+			}
+			if vm.incrementIOnLoadStore() {
+				vm.i += x + 1
 			}
 		case 0x0065:
-			// Fill the registers V0-VX with values from memory
-			// starting at address I. I is increased by 1 for each
-			// value written.
+			// Fill the registers V0-VX, inclusive, with values from
+			// memory starting at address I.
+			//
+			// NOTE: on VIP/XO-CHIP, I is left advanced by the number
+			// of values read; SCHIP leaves it unchanged. See
+			// incrementIOnLoadStore.
+			x := op.RegisterIndex(true)
+
+			for i := uint16(0); i <= x; i++ {
+				vm.v[i] = vm.memory[vm.i+i]
+			}
+			if vm.incrementIOnLoadStore() {
+				vm.i += x + 1
+			}
+		case 0x0075:
+			// Save V0-VX to the persistent RPL user flags.
+			// (SCHIP/XO-CHIP)
 			x := op.RegisterIndex(true)
 
-			for i := uint16(0); i < x; i++ {
-				vm.v[i] = vm.memory[vm.i]
-				vm.i++
+			for i := uint16(0); i <= x && i < numRPLFlags; i++ {
+				vm.rpl[i] = vm.v[i]
+			}
+
+			if err := vm.saveRPLFlags(); err != nil {
+				return fmt.Errorf("failed saving rpl flags: %v", err)
+			}
+		case 0x0085:
+			// Load V0-VX from the persistent RPL user flags.
+			// (SCHIP/XO-CHIP)
+			x := op.RegisterIndex(true)
+
+			if err := vm.loadRPLFlags(); err != nil {
+				return fmt.Errorf("failed loading rpl flags: %v", err)
+			}
+
+			for i := uint16(0); i <= x && i < numRPLFlags; i++ {
+				vm.v[i] = vm.rpl[i]
 			}
 		default:
 			return ErrUnknownOpcode
@@ -573,21 +992,126 @@ func (vm *VirtualMachine) execute(op opcode) error {
 	return nil
 }
 
-// PressKey signals the virtual machine that the key was pressed.
+// PressKey signals the virtual machine that the key was pressed. It has no
+// effect if the configured InputSource does not implement KeyInput.
 func (vm *VirtualMachine) PressKey(idx int) {
 	vm.updateKeyState(idx, true)
 }
 
-// ReleaseKey signals the virtual machine that the key was released.
+// ReleaseKey signals the virtual machine that the key was released. It has
+// no effect if the configured InputSource does not implement KeyInput.
 func (vm *VirtualMachine) ReleaseKey(idx int) {
 	vm.updateKeyState(idx, false)
 }
 
-// updateKeyState updates the state of a key.
+// updateKeyState updates the state of a key on the virtual machine's
+// InputSource, if it implements KeyInput.
 func (vm *VirtualMachine) updateKeyState(idx int, pressed bool) {
 	if idx < 0 || idx >= numKeys {
 		return
 	}
 
-	vm.keys[idx] = pressed
+	ki, ok := vm.input.(KeyInput)
+	if !ok {
+		return
+	}
+
+	if pressed {
+		ki.PressKey(uint8(idx))
+	} else {
+		ki.ReleaseKey(uint8(idx))
+	}
+}
+
+// waitKey blocks on InputSource.WaitKey for the FX0A opcode. run holds
+// stateMu for the duration of step, so waitKey releases it first —
+// otherwise Snapshot and Debugger.State would hang for as long as the ROM
+// goes unanswered.
+func (vm *VirtualMachine) waitKey() (uint8, error) {
+	vm.stateMu.Unlock()
+	defer vm.stateMu.Lock()
+
+	return vm.input.WaitKey(vm.quitContext())
+}
+
+// quitContext returns a context that is cancelled once the virtual
+// machine's quit channel is closed, used to unblock InputSource.WaitKey
+// when Stop is called while the CPU goroutine is waiting on FX0A.
+func (vm *VirtualMachine) quitContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		select {
+		case <-vm.quit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx
+}
+
+// shiftInPlace reports whether the 8XY6/8XYE opcodes should shift VX in
+// place, ignoring VY, which is the behavior SCHIP and XO-CHIP programs
+// expect. The original VIP interpreter instead shifts VY and copies the
+// result into VX.
+func (vm *VirtualMachine) shiftInPlace() bool {
+	return vm.mode != ModeVIP
+}
+
+// clips reports whether drawSprite/drawSprite16 should clip sprites at the
+// display's edges instead of wrapping them to the opposite edge. The
+// original VIP interpreter wraps; SCHIP and XO-CHIP clip. Options.Quirks
+// overrides this default.
+func (vm *VirtualMachine) clips() bool {
+	switch vm.quirks.Clipping {
+	case ClippingWrap:
+		return false
+	case ClippingClip:
+		return true
+	default:
+		return vm.mode != ModeVIP
+	}
+}
+
+// incrementIOnLoadStore reports whether the FX55/FX65 opcodes should leave
+// the address register advanced by the number of registers transferred.
+// This matches the VIP and XO-CHIP, but not SCHIP, which leaves I
+// unchanged.
+func (vm *VirtualMachine) incrementIOnLoadStore() bool {
+	return vm.mode != ModeSuperChip
+}
+
+// saveRPLFlags persists the RPL user flags to rplPath, if one was
+// configured.
+func (vm *VirtualMachine) saveRPLFlags() error {
+	if vm.rplPath == "" {
+		return nil
+	}
+
+	return ioutil.WriteFile(vm.rplPath, vm.rpl[:], 0o644)
+}
+
+// loadRPLFlags loads the RPL user flags from rplPath, if one was
+// configured. It is not an error for the file not to exist yet.
+func (vm *VirtualMachine) loadRPLFlags() error {
+	if vm.rplPath == "" {
+		return nil
+	}
+
+	data, err := ioutil.ReadFile(vm.rplPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	n := copy(vm.rpl[:], data)
+	for i := n; i < len(vm.rpl); i++ {
+		vm.rpl[i] = 0
+	}
+
+	return nil
 }